@@ -0,0 +1,239 @@
+// This example extends the sum_and_difference example to show how to
+// configure an ort.SessionOptions instance to run on a GPU, using either the
+// CUDA or TensorRT execution providers. It reuses the same tiny network as
+// sum_and_difference (see the generate_network.py script in that directory),
+// since the point of this example is the SessionOptions setup rather than
+// the network itself.
+//
+// Most onnxruntime shared libraries are *not* built with CUDA or TensorRT
+// support, so this program first attempts to append whichever GPU execution
+// provider was requested on the command line, and falls back to plain CPU
+// execution (by simply not appending any GPU execution providers) if that
+// fails. This mirrors the approach users typically need to take when
+// deploying onnxruntime_go binaries across machines that may or may not
+// have a GPU and the matching onnxruntime shared library available.
+package main
+
+import (
+	"flag"
+	"fmt"
+	ort "github.com/yalue/onnxruntime_go"
+	"os"
+	"runtime"
+)
+
+// Attempts to find and return a path to a version of the onnxruntime shared
+// library compatible with the current OS and system architecture. Note that
+// GPU execution providers additionally require a GPU-enabled build of the
+// shared library; the CPU-only libraries bundled under third_party won't
+// support CUDA or TensorRT even though they'll still load successfully.
+func getDefaultSharedLibPath() string {
+	if runtime.GOOS == "windows" {
+		if runtime.GOARCH == "amd64" {
+			return "../third_party/onnxruntime.dll"
+		}
+	}
+	if runtime.GOOS == "darwin" {
+		if runtime.GOARCH == "arm64" {
+			return "../third_party/onnxruntime_arm64.dylib"
+		}
+	}
+	if runtime.GOOS == "linux" {
+		if runtime.GOARCH == "arm64" {
+			return "../third_party/onnxruntime_arm64.so"
+		}
+		return "../third_party/onnxruntime.so"
+	}
+	fmt.Printf("Unable to determine a path to the onnxruntime shared library"+
+		" for OS \"%s\" and architecture \"%s\".\n", runtime.GOOS,
+		runtime.GOARCH)
+	return ""
+}
+
+// Holds the command-line-configurable knobs for this example's
+// ort.SessionOptions.
+type gpuConfig struct {
+	useCUDA                bool
+	useTensorRT            bool
+	deviceID               int
+	interOpNumThreads      int
+	intraOpNumThreads      int
+	graphOptimizationLevel ort.GraphOptimizationLevel
+}
+
+// Builds a SessionOptions instance configured according to c, and attempts
+// to append the requested GPU execution provider. If appending the GPU
+// execution provider fails (for example, because the shared library wasn't
+// built with CUDA or TensorRT support), this prints a warning and returns
+// options that will still run correctly on the CPU. The returned string
+// describes which execution provider will actually be used.
+func buildSessionOptions(c gpuConfig) (*ort.SessionOptions, string, error) {
+	options, e := ort.NewSessionOptions()
+	if e != nil {
+		return nil, "", fmt.Errorf("Error creating SessionOptions: %w", e)
+	}
+
+	if e := options.SetInterOpNumThreads(c.interOpNumThreads); e != nil {
+		options.Destroy()
+		return nil, "", fmt.Errorf("Error setting InterOpNumThreads: %w", e)
+	}
+	if e := options.SetIntraOpNumThreads(c.intraOpNumThreads); e != nil {
+		options.Destroy()
+		return nil, "", fmt.Errorf("Error setting IntraOpNumThreads: %w", e)
+	}
+	if e := options.SetGraphOptimizationLevel(c.graphOptimizationLevel); e != nil {
+		options.Destroy()
+		return nil, "", fmt.Errorf("Error setting the graph optimization "+
+			"level: %w", e)
+	}
+
+	provider := "CPU"
+	if c.useTensorRT {
+		trtOptions, e := ort.NewTensorRTProviderOptions()
+		if e != nil {
+			fmt.Printf("Warning: couldn't create TensorRT provider "+
+				"options (%s); falling back to CPU.\n", e)
+		} else {
+			defer trtOptions.Destroy()
+			e = trtOptions.Update(map[string]string{
+				"device_id": fmt.Sprintf("%d", c.deviceID),
+			})
+			if e == nil {
+				e = options.AppendExecutionProviderTensorRT(trtOptions)
+			}
+			if e != nil {
+				fmt.Printf("Warning: TensorRT execution provider isn't "+
+					"available (%s); falling back to CPU.\n", e)
+			} else {
+				provider = "TensorRT"
+			}
+		}
+	}
+	if provider == "CPU" && c.useCUDA {
+		cudaOptions, e := ort.NewCUDAProviderOptions()
+		if e != nil {
+			fmt.Printf("Warning: couldn't create CUDA provider options "+
+				"(%s); falling back to CPU.\n", e)
+		} else {
+			defer cudaOptions.Destroy()
+			e = cudaOptions.Update(map[string]string{
+				"device_id": fmt.Sprintf("%d", c.deviceID),
+			})
+			if e == nil {
+				e = options.AppendExecutionProviderCUDA(cudaOptions)
+			}
+			if e != nil {
+				fmt.Printf("Warning: CUDA execution provider isn't "+
+					"available (%s); falling back to CPU.\n", e)
+			} else {
+				provider = "CUDA"
+			}
+		}
+	}
+
+	return options, provider, nil
+}
+
+// Sets up and runs the same network as sum_and_difference, but using
+// SessionOptions configured for GPU execution where possible.
+func runTest(onnxruntimeLibPath string, c gpuConfig) error {
+	ort.SetSharedLibraryPath(onnxruntimeLibPath)
+	e := ort.InitializeEnvironment()
+	if e != nil {
+		return fmt.Errorf("Error initializing the onnxruntime library: %w", e)
+	}
+	defer ort.DestroyEnvironment()
+
+	options, provider, e := buildSessionOptions(c)
+	if e != nil {
+		return e
+	}
+	defer options.Destroy()
+
+	inputData := []float32{0.2, 0.3, 0.6, 0.9}
+	inputTensor, e := ort.NewTensor(ort.NewShape(1, 1, 4), inputData)
+	if e != nil {
+		return fmt.Errorf("Error creating the input tensor: %w", e)
+	}
+	defer inputTensor.Destroy()
+
+	outputTensor, e := ort.NewEmptyTensor[float32](ort.NewShape(1, 1, 2))
+	if e != nil {
+		return fmt.Errorf("Error creating the output tensor: %w", e)
+	}
+	defer outputTensor.Destroy()
+
+	session, e := ort.NewAdvancedSession("../sum_and_difference/sum_and_difference.onnx",
+		[]string{"1x4 Input Vector"},
+		[]string{"1x2 Output Vector"},
+		[]ort.ArbitraryTensor{inputTensor},
+		[]ort.ArbitraryTensor{outputTensor},
+		options)
+	if e != nil {
+		return fmt.Errorf("Error creating the session: %w", e)
+	}
+	defer session.Destroy()
+
+	e = session.Run()
+	if e != nil {
+		return fmt.Errorf("Error executing the network: %w", e)
+	}
+
+	outputData := outputTensor.GetData()
+	fmt.Printf("The network ran without errors, using the %s execution "+
+		"provider.\n", provider)
+	fmt.Printf("  Input data: %v\n", inputData)
+	fmt.Printf("  Approximate sum of inputs: %f\n", outputData[0])
+	fmt.Printf("  Approximate max difference between any two inputs: %f\n", outputData[1])
+	return nil
+}
+
+func run() int {
+	var onnxruntimeLibPath string
+	var c gpuConfig
+	var optLevel int
+	flag.StringVar(&onnxruntimeLibPath, "onnxruntime_lib",
+		getDefaultSharedLibPath(),
+		"The path to the onnxruntime shared library for your system.")
+	flag.BoolVar(&c.useCUDA, "cuda", false,
+		"Attempt to run on the CUDA execution provider.")
+	flag.BoolVar(&c.useTensorRT, "tensorrt", false,
+		"Attempt to run on the TensorRT execution provider. Takes "+
+			"priority over -cuda if both are set.")
+	flag.IntVar(&c.deviceID, "device_id", 0,
+		"The GPU device ID to run on, if a GPU execution provider is used.")
+	flag.IntVar(&c.interOpNumThreads, "inter_op_threads", 1,
+		"The number of threads used to parallelize execution across nodes.")
+	flag.IntVar(&c.intraOpNumThreads, "intra_op_threads", 1,
+		"The number of threads used to parallelize execution within nodes.")
+	flag.IntVar(&optLevel, "graph_optimization_level", 3,
+		"The graph optimization level to use, 0 (disabled) to 3 (all).")
+	flag.Parse()
+	if onnxruntimeLibPath == "" {
+		fmt.Println("You must specify a path to the onnxruntime shared " +
+			"on your system. Run with -help for more information.")
+		return 1
+	}
+	switch optLevel {
+	case 0:
+		c.graphOptimizationLevel = ort.GraphOptimizationLevelDisableAll
+	case 1:
+		c.graphOptimizationLevel = ort.GraphOptimizationLevelEnableBasic
+	case 2:
+		c.graphOptimizationLevel = ort.GraphOptimizationLevelEnableExtended
+	default:
+		c.graphOptimizationLevel = ort.GraphOptimizationLevelEnableAll
+	}
+
+	e := runTest(onnxruntimeLibPath, c)
+	if e != nil {
+		fmt.Printf("Encountered an error running the network: %s\n", e)
+		return 1
+	}
+	fmt.Printf("The network seemed to run OK!\n")
+	return 0
+}
+
+func main() {
+	os.Exit(run())
+}
@@ -0,0 +1,195 @@
+// This example loads an ImageNet-class image classification network (for
+// example, a ResNet50 or MobileNetV2 model exported to ONNX) and runs it on
+// a single JPEG or PNG image supplied on the command line, printing the
+// top-5 predicted class labels and their probabilities.
+//
+// The pre- and post-processing steps used here---letterbox-free resizing to
+// 224x224, HWC-to-CHW conversion, per-channel normalization, and softmax---
+// are implemented in the imageutil subpackage so that later examples (such
+// as yolo_detection) can reuse them instead of duplicating this logic.
+//
+// This example expects a plain-text labels file, with one class name per
+// line in the same order as the network's output vector (line 0 is class
+// 0, and so on). The standard 1000-line ImageNet synset word list bundled
+// with most ONNX model zoo classification models (for example, the one
+// shipped alongside torchvision's pretrained weights) works directly; it is
+// not included in this repository since, like the .onnx model files
+// themselves, it's a third-party data file rather than source code.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	ort "github.com/yalue/onnxruntime_go"
+	"os"
+	"runtime"
+
+	"github.com/yalue/onnxruntime_go_examples/imageutil"
+)
+
+// Attempts to find and return a path to a version of the onnxruntime shared
+// library compatible with the current OS and system architecture.
+func getDefaultSharedLibPath() string {
+	if runtime.GOOS == "windows" {
+		if runtime.GOARCH == "amd64" {
+			return "../third_party/onnxruntime.dll"
+		}
+	}
+	if runtime.GOOS == "darwin" {
+		if runtime.GOARCH == "arm64" {
+			return "../third_party/onnxruntime_arm64.dylib"
+		}
+	}
+	if runtime.GOOS == "linux" {
+		if runtime.GOARCH == "arm64" {
+			return "../third_party/onnxruntime_arm64.so"
+		}
+		return "../third_party/onnxruntime.so"
+	}
+	fmt.Printf("Unable to determine a path to the onnxruntime shared library"+
+		" for OS \"%s\" and architecture \"%s\".\n", runtime.GOOS,
+		runtime.GOARCH)
+	return ""
+}
+
+// The standard ImageNet per-channel mean and standard deviation, in RGB
+// order, used to normalize inputs for most torchvision-trained networks.
+var imagenetMean = [3]float32{0.485, 0.456, 0.406}
+var imagenetStd = [3]float32{0.229, 0.224, 0.225}
+
+// Reads a newline-delimited list of class labels from path.
+func readLabels(path string) ([]string, error) {
+	f, e := os.Open(path)
+	if e != nil {
+		return nil, fmt.Errorf("Error opening labels file: %w", e)
+	}
+	defer f.Close()
+	var labels []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		labels = append(labels, scanner.Text())
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, fmt.Errorf("Error reading labels file: %w", e)
+	}
+	return labels, nil
+}
+
+// Holds the command-line configuration for this example.
+type config struct {
+	onnxruntimeLibPath string
+	modelPath          string
+	imagePath          string
+	labelsPath         string
+	inputName          string
+	outputName         string
+	inputSize          int
+	topK               int
+}
+
+// Loads the image at c.imagePath, preprocesses it, runs it through the
+// network at c.modelPath, and prints the top-K predicted labels.
+func runClassifier(c config) error {
+	ort.SetSharedLibraryPath(c.onnxruntimeLibPath)
+	e := ort.InitializeEnvironment()
+	if e != nil {
+		return fmt.Errorf("Error initializing the onnxruntime library: %w", e)
+	}
+	defer ort.DestroyEnvironment()
+
+	labels, e := readLabels(c.labelsPath)
+	if e != nil {
+		return e
+	}
+
+	img, e := imageutil.DecodeImageFile(c.imagePath)
+	if e != nil {
+		return fmt.Errorf("Error decoding %s: %w", c.imagePath, e)
+	}
+	resized := imageutil.Resize(img, c.inputSize, c.inputSize)
+	inputData := imageutil.ToCHWFloat32(resized, imagenetMean, imagenetStd)
+
+	inputTensor, e := ort.NewTensor(ort.NewShape(1, 3, int64(c.inputSize),
+		int64(c.inputSize)), inputData)
+	if e != nil {
+		return fmt.Errorf("Error creating the input tensor: %w", e)
+	}
+	defer inputTensor.Destroy()
+
+	outputTensor, e := ort.NewEmptyTensor[float32](ort.NewShape(1,
+		int64(len(labels))))
+	if e != nil {
+		return fmt.Errorf("Error creating the output tensor: %w", e)
+	}
+	defer outputTensor.Destroy()
+
+	session, e := ort.NewAdvancedSession(c.modelPath,
+		[]string{c.inputName}, []string{c.outputName},
+		[]ort.ArbitraryTensor{inputTensor},
+		[]ort.ArbitraryTensor{outputTensor}, nil)
+	if e != nil {
+		return fmt.Errorf("Error creating the session: %w", e)
+	}
+	defer session.Destroy()
+
+	if e := session.Run(); e != nil {
+		return fmt.Errorf("Error running the network: %w", e)
+	}
+
+	probabilities := imageutil.Softmax(outputTensor.GetData())
+	top, e := imageutil.TopK(probabilities, labels, c.topK)
+	if e != nil {
+		return fmt.Errorf("Error ranking predictions: %w", e)
+	}
+
+	fmt.Printf("Top %d predictions for %s:\n", len(top), c.imagePath)
+	for i, result := range top {
+		fmt.Printf("  %d. %-30s %.2f%%\n", i+1, result.Label,
+			result.Score*100)
+	}
+	return nil
+}
+
+func run() int {
+	var c config
+	flag.StringVar(&c.onnxruntimeLibPath, "onnxruntime_lib",
+		getDefaultSharedLibPath(),
+		"The path to the onnxruntime shared library for your system.")
+	flag.StringVar(&c.modelPath, "model", "./resnet50.onnx",
+		"The path to the ONNX classification model to run.")
+	flag.StringVar(&c.imagePath, "image", "",
+		"The path to a JPEG or PNG image to classify.")
+	flag.StringVar(&c.labelsPath, "labels", "./imagenet_classes.txt",
+		"The path to a newline-delimited list of class labels.")
+	flag.StringVar(&c.inputName, "input_name", "input",
+		"The name of the network's input tensor.")
+	flag.StringVar(&c.outputName, "output_name", "output",
+		"The name of the network's output tensor.")
+	flag.IntVar(&c.inputSize, "input_size", 224,
+		"The height and width, in pixels, expected by the network.")
+	flag.IntVar(&c.topK, "top_k", 5,
+		"The number of top predictions to print.")
+	flag.Parse()
+	if c.onnxruntimeLibPath == "" {
+		fmt.Println("You must specify a path to the onnxruntime shared " +
+			"on your system. Run with -help for more information.")
+		return 1
+	}
+	if c.imagePath == "" {
+		fmt.Println("You must specify an -image to classify. Run with " +
+			"-help for more information.")
+		return 1
+	}
+
+	e := runClassifier(c)
+	if e != nil {
+		fmt.Printf("Encountered an error classifying the image: %s\n", e)
+		return 1
+	}
+	return 0
+}
+
+func main() {
+	os.Exit(run())
+}
@@ -0,0 +1,105 @@
+package imageutil
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	stddraw "image/draw"
+	"image/png"
+	"os"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// The width, in pixels, of the box outline drawn by DrawDetections.
+const boxLineWidth = 2
+
+// Draws an axis-aligned rectangle outline onto dst in the given color,
+// clamped to dst's bounds.
+func drawRect(dst stddraw.Image, x1, y1, x2, y2 int, c color.Color) {
+	bounds := dst.Bounds()
+	clamp := func(v, lo, hi int) int {
+		if v < lo {
+			return lo
+		}
+		if v > hi {
+			return hi
+		}
+		return v
+	}
+	x1 = clamp(x1, bounds.Min.X, bounds.Max.X-1)
+	x2 = clamp(x2, bounds.Min.X, bounds.Max.X-1)
+	y1 = clamp(y1, bounds.Min.Y, bounds.Max.Y-1)
+	y2 = clamp(y2, bounds.Min.Y, bounds.Max.Y-1)
+	for thickness := 0; thickness < boxLineWidth; thickness++ {
+		for x := x1; x <= x2; x++ {
+			dst.Set(x, y1+thickness, c)
+			dst.Set(x, y2-thickness, c)
+		}
+		for y := y1; y <= y2; y++ {
+			dst.Set(x1+thickness, y, c)
+			dst.Set(x2-thickness, y, c)
+		}
+	}
+}
+
+// A small, fixed palette used to color-code detection boxes by class index,
+// so that repeated classes are visually distinguishable without needing a
+// full color-mapping configuration.
+var boxColors = []color.Color{
+	color.RGBA{R: 255, G: 64, B: 64, A: 255},
+	color.RGBA{R: 64, G: 255, B: 64, A: 255},
+	color.RGBA{R: 64, G: 128, B: 255, A: 255},
+	color.RGBA{R: 255, G: 215, B: 0, A: 255},
+	color.RGBA{R: 255, G: 64, B: 255, A: 255},
+	color.RGBA{R: 64, G: 255, B: 255, A: 255},
+}
+
+// Renders detections onto a copy of src, drawing each bounding box along
+// with a "label score%" caption above it (or just inside its top edge, if
+// the box is too close to the top of the image), and writes the result as a
+// PNG to outputPath. labels[d.ClassID] is used as the caption text for detection
+// d.
+func DrawDetections(src image.Image, detections []Detection, labels []string,
+	outputPath string) error {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(bounds)
+	stddraw.Draw(dst, bounds, src, bounds.Min, stddraw.Src)
+
+	face := basicfont.Face7x13
+	for _, d := range detections {
+		c := boxColors[d.ClassID%len(boxColors)]
+		x1, y1 := int(d.X1), int(d.Y1)
+		x2, y2 := int(d.X2), int(d.Y2)
+		drawRect(dst, x1, y1, x2, y2, c)
+
+		label := fmt.Sprintf("class_%d", d.ClassID)
+		if d.ClassID >= 0 && d.ClassID < len(labels) {
+			label = labels[d.ClassID]
+		}
+		caption := fmt.Sprintf("%s %.0f%%", label, d.Score*100)
+		textY := y1 - 4
+		if textY < face.Metrics().Height.Ceil() {
+			textY = y1 + face.Metrics().Height.Ceil()
+		}
+		drawer := &font.Drawer{
+			Dst:  dst,
+			Src:  image.NewUniform(c),
+			Face: face,
+			Dot:  fixed.P(x1, textY),
+		}
+		drawer.DrawString(caption)
+	}
+
+	f, e := os.Create(outputPath)
+	if e != nil {
+		return fmt.Errorf("Error creating %s: %w", outputPath, e)
+	}
+	defer f.Close()
+	if e := png.Encode(f, dst); e != nil {
+		return fmt.Errorf("Error encoding PNG to %s: %w", outputPath, e)
+	}
+	return nil
+}
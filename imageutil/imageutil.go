@@ -0,0 +1,165 @@
+// Package imageutil implements the small set of image pre- and
+// post-processing helpers shared by this repository's image-based examples
+// (image_classification, and later yolo_detection). None of this is
+// onnxruntime-specific; it just turns decoded images into the NCHW
+// []float32 tensors these networks expect, and turns model outputs back
+// into something human-readable.
+package imageutil
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+	"sort"
+
+	"golang.org/x/image/draw"
+)
+
+// Decodes the JPEG or PNG image stored at path, returning the decoded
+// image. The underlying image format is detected automatically from the
+// file's contents.
+func DecodeImageFile(path string) (image.Image, error) {
+	f, e := os.Open(path)
+	if e != nil {
+		return nil, fmt.Errorf("Error opening %s: %w", path, e)
+	}
+	defer f.Close()
+	img, _, e := image.Decode(f)
+	if e != nil {
+		return nil, fmt.Errorf("Error decoding %s: %w", path, e)
+	}
+	return img, nil
+}
+
+// Describes how a source image was mapped onto a letterboxed square: the
+// uniform scale factor that was applied to the source image's dimensions,
+// and the number of padding pixels added on the left and top edges (the
+// remaining padding, if any, is on the right and bottom edges). This is
+// enough information to map detection boxes in the letterboxed image's
+// coordinate space back to the original image's coordinate space.
+type LetterboxInfo struct {
+	Scale float64
+	PadX  int
+	PadY  int
+}
+
+// Resizes img to fit within a size x size square while preserving its
+// aspect ratio, and pads the remaining space with mid-gray (114, 114, 114),
+// matching the letterboxing convention used by the YOLO family of models.
+// Returns the resulting square image along with the information needed to
+// map coordinates back to the original image via LetterboxInfo.MapToSource.
+func Letterbox(img image.Image, size int) (image.Image, LetterboxInfo) {
+	srcBounds := img.Bounds()
+	srcW, srcH := srcBounds.Dx(), srcBounds.Dy()
+	scale := float64(size) / float64(srcW)
+	if alt := float64(size) / float64(srcH); alt < scale {
+		scale = alt
+	}
+	scaledW := int(float64(srcW)*scale + 0.5)
+	scaledH := int(float64(srcH)*scale + 0.5)
+	padX := (size - scaledW) / 2
+	padY := (size - scaledH) / 2
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	gray := image.NewUniform(color.RGBA{R: 114, G: 114, B: 114, A: 255})
+	draw.Draw(dst, dst.Bounds(), gray, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, image.Rect(padX, padY, padX+scaledW,
+		padY+scaledH), img, srcBounds, draw.Over, nil)
+
+	return dst, LetterboxInfo{Scale: scale, PadX: padX, PadY: padY}
+}
+
+// Maps a point's coordinates in the letterboxed image produced by Letterbox
+// back to the corresponding coordinates in the original source image.
+func (l LetterboxInfo) MapToSource(x, y float32) (float32, float32) {
+	srcX := (x - float32(l.PadX)) / float32(l.Scale)
+	srcY := (y - float32(l.PadY)) / float32(l.Scale)
+	return srcX, srcY
+}
+
+// Resizes img to exactly width x height, ignoring aspect ratio. This is
+// used by models such as ResNet and MobileNet that expect a fixed-size
+// square input rather than a letterboxed one.
+func Resize(img image.Image, width, height int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return dst
+}
+
+// Converts img to an NCHW []float32 slice (batch size 1), normalizing each
+// of the three color channels independently using mean[c] and std[c]:
+// normalized = (pixel / 255 - mean[c]) / std[c]. This is the standard
+// normalization used by ImageNet-trained classification networks.
+func ToCHWFloat32(img image.Image, mean, std [3]float32) []float32 {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	planeSize := w * h
+	out := make([]float32, 3*planeSize)
+	i := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			// RGBA() returns 16-bit-per-channel values; scale down to 0-255.
+			out[0*planeSize+i] = (float32(r>>8)/255.0 - mean[0]) / std[0]
+			out[1*planeSize+i] = (float32(g>>8)/255.0 - mean[1]) / std[1]
+			out[2*planeSize+i] = (float32(b>>8)/255.0 - mean[2]) / std[2]
+			i++
+		}
+	}
+	return out
+}
+
+// Applies the softmax function to logits in place, and also returns the
+// slice for convenience.
+func Softmax(logits []float32) []float32 {
+	if len(logits) == 0 {
+		return logits
+	}
+	max := logits[0]
+	for _, v := range logits[1:] {
+		if v > max {
+			max = v
+		}
+	}
+	var sum float32
+	for i, v := range logits {
+		e := float32(math.Exp(float64(v - max)))
+		logits[i] = e
+		sum += e
+	}
+	for i := range logits {
+		logits[i] /= sum
+	}
+	return logits
+}
+
+// A single classification result: a class label paired with its score.
+type ClassScore struct {
+	Label string
+	Score float32
+}
+
+// Returns the k classes with the highest scores, sorted from highest to
+// lowest. labels[i] must be the class name corresponding to scores[i]; k is
+// clamped to len(scores) if necessary.
+func TopK(scores []float32, labels []string, k int) ([]ClassScore, error) {
+	if len(scores) != len(labels) {
+		return nil, fmt.Errorf("Got %d scores but %d labels", len(scores),
+			len(labels))
+	}
+	if k > len(scores) {
+		k = len(scores)
+	}
+	results := make([]ClassScore, len(scores))
+	for i, s := range scores {
+		results[i] = ClassScore{Label: labels[i], Score: s}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results[:k], nil
+}
@@ -0,0 +1,99 @@
+package imageutil
+
+import "sort"
+
+// A single detected object: an axis-aligned bounding box in pixel
+// coordinates (of whatever image the detection was produced against, e.g.
+// a letterboxed input), a class index, and a confidence score.
+type Detection struct {
+	X1, Y1, X2, Y2 float32
+	ClassID        int
+	Score          float32
+}
+
+// Returns the area of d's bounding box, or 0 if the box has zero or
+// negative width or height.
+func (d Detection) area() float32 {
+	w := d.X2 - d.X1
+	h := d.Y2 - d.Y1
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	return w * h
+}
+
+// Returns the intersection-over-union of a and b's bounding boxes. Returns
+// 0 if the boxes don't overlap, or if either box has zero area.
+func iou(a, b Detection) float32 {
+	x1 := max32(a.X1, b.X1)
+	y1 := max32(a.Y1, b.Y1)
+	x2 := min32(a.X2, b.X2)
+	y2 := min32(a.Y2, b.Y2)
+	interW := x2 - x1
+	interH := y2 - y1
+	if interW <= 0 || interH <= 0 {
+		return 0
+	}
+	intersection := interW * interH
+	union := a.area() + b.area() - intersection
+	if union <= 0 {
+		return 0
+	}
+	return intersection / union
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Performs greedy Non-Maximum Suppression on detections: sorts them by
+// score descending, then repeatedly keeps the highest-scoring remaining
+// detection and discards any others whose IoU with it exceeds
+// iouThreshold. If classAgnostic is false, only detections sharing the
+// same ClassID are compared against each other; if true, detections of
+// different classes can still suppress one another. detections with zero
+// area are dropped unconditionally, and an empty input returns an empty,
+// non-nil slice.
+func NMS(detections []Detection, iouThreshold float32,
+	classAgnostic bool) []Detection {
+	candidates := make([]Detection, 0, len(detections))
+	for _, d := range detections {
+		if d.area() > 0 {
+			candidates = append(candidates, d)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	kept := make([]Detection, 0, len(candidates))
+	suppressed := make([]bool, len(candidates))
+	for i := range candidates {
+		if suppressed[i] {
+			continue
+		}
+		kept = append(kept, candidates[i])
+		for j := i + 1; j < len(candidates); j++ {
+			if suppressed[j] {
+				continue
+			}
+			if !classAgnostic && candidates[i].ClassID != candidates[j].ClassID {
+				continue
+			}
+			if iou(candidates[i], candidates[j]) > iouThreshold {
+				suppressed[j] = true
+			}
+		}
+	}
+	return kept
+}
@@ -0,0 +1,104 @@
+// This is a minimal, bundled alternative to a tool like `hey` for
+// benchmarking the server example's throughput: it fires a configurable
+// number of concurrent workers at a POST /infer endpoint for a fixed
+// duration and reports the number of completed requests, the throughput in
+// requests per second, and the mean request latency. Comparing throughput
+// across different -pool_size values on the server is what this is for.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The fixed request body this tool sends; the server example only cares
+// about the number of values, not their content.
+var requestBody = []byte(`{"input": [0.2, 0.3, 0.6, 0.9]}`)
+
+// Holds the command-line configuration for this tool.
+type config struct {
+	url      string
+	workers  int
+	duration time.Duration
+}
+
+// Repeatedly sends requests to c.url until stop is closed, incrementing
+// completed for every successful response and failed for every error or
+// non-200 response. Accumulates the total latency of successful requests
+// into totalLatencyNanos so the caller can compute a mean afterward.
+func worker(c config, client *http.Client, stop <-chan struct{},
+	completed, failed *int64, totalLatencyNanos *int64, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		start := time.Now()
+		resp, e := client.Post(c.url, "application/json",
+			bytes.NewReader(requestBody))
+		elapsed := time.Since(start)
+		if e != nil {
+			atomic.AddInt64(failed, 1)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			atomic.AddInt64(failed, 1)
+			continue
+		}
+		atomic.AddInt64(completed, 1)
+		atomic.AddInt64(totalLatencyNanos, elapsed.Nanoseconds())
+	}
+}
+
+func runLoadTest(c config) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	var completed, failed, totalLatencyNanos int64
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go worker(c, client, stop, &completed, &failed, &totalLatencyNanos,
+			&wg)
+	}
+
+	start := time.Now()
+	time.Sleep(c.duration)
+	close(stop)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	completedCount := atomic.LoadInt64(&completed)
+	failedCount := atomic.LoadInt64(&failed)
+	fmt.Printf("Workers:           %d\n", c.workers)
+	fmt.Printf("Duration:          %s\n", elapsed)
+	fmt.Printf("Completed requests: %d\n", completedCount)
+	fmt.Printf("Failed requests:    %d\n", failedCount)
+	fmt.Printf("Throughput:        %.1f req/s\n",
+		float64(completedCount)/elapsed.Seconds())
+	if completedCount > 0 {
+		meanLatency := time.Duration(totalLatencyNanos / completedCount)
+		fmt.Printf("Mean latency:      %s\n", meanLatency)
+	}
+}
+
+func main() {
+	var c config
+	flag.StringVar(&c.url, "url", "http://127.0.0.1:8080/infer",
+		"The URL of the server example's /infer endpoint.")
+	flag.IntVar(&c.workers, "workers", 8,
+		"The number of concurrent workers sending requests.")
+	flag.DurationVar(&c.duration, "duration", 10*time.Second,
+		"How long to run the load test for.")
+	flag.Parse()
+
+	runLoadTest(c)
+}
@@ -0,0 +1,304 @@
+// This example wraps the sum_and_difference network behind an HTTP
+// inference server exposing POST /infer, accepting JSON
+// {"input": [a, b, c, d]} and returning JSON {"output": [sum, maxDiff]}.
+//
+// A single ort.AdvancedSession's Run() is not safe to call concurrently,
+// since concurrent calls would race on that session's shared input and
+// output tensors. Rather than serializing every request behind one global
+// lock, this example pre-builds a fixed-size pool of sessions---each with
+// its own input and output tensors---and hands them out to request
+// handlers via a buffered channel. This is the same concurrency pattern
+// used for any non-thread-safe, expensive-to-create resource (database
+// connections being the most common example), applied to onnxruntime
+// sessions.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	ort "github.com/yalue/onnxruntime_go"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// Attempts to find and return a path to a version of the onnxruntime shared
+// library compatible with the current OS and system architecture.
+func getDefaultSharedLibPath() string {
+	if runtime.GOOS == "windows" {
+		if runtime.GOARCH == "amd64" {
+			return "../third_party/onnxruntime.dll"
+		}
+	}
+	if runtime.GOOS == "darwin" {
+		if runtime.GOARCH == "arm64" {
+			return "../third_party/onnxruntime_arm64.dylib"
+		}
+	}
+	if runtime.GOOS == "linux" {
+		if runtime.GOARCH == "arm64" {
+			return "../third_party/onnxruntime_arm64.so"
+		}
+		return "../third_party/onnxruntime.so"
+	}
+	fmt.Printf("Unable to determine a path to the onnxruntime shared library"+
+		" for OS \"%s\" and architecture \"%s\".\n", runtime.GOOS,
+		runtime.GOARCH)
+	return ""
+}
+
+// One entry in the session pool: a session along with the input and output
+// tensors it was created with. Each pooledSession may only be used by one
+// goroutine at a time; the SessionPool enforces this.
+type pooledSession struct {
+	session *ort.AdvancedSession
+	input   *ort.Tensor[float32]
+	output  *ort.Tensor[float32]
+}
+
+// Destroys the session and its tensors. The session must not be in use, and
+// must not be returned to its pool afterwards.
+func (p *pooledSession) destroy() {
+	p.session.Destroy()
+	p.input.Destroy()
+	p.output.Destroy()
+}
+
+// Runs the network on the given input values, returning the output values.
+// Must only be called by whichever goroutine currently owns p (i.e., that
+// obtained it from SessionPool.Get).
+func (p *pooledSession) run(input []float32) ([]float32, error) {
+	copy(p.input.GetData(), input)
+	if e := p.session.Run(); e != nil {
+		return nil, fmt.Errorf("Error running the network: %w", e)
+	}
+	output := make([]float32, len(p.output.GetData()))
+	copy(output, p.output.GetData())
+	return output, nil
+}
+
+// A fixed-size pool of pre-built sessions, each usable by only one
+// goroutine at a time. Handed out and returned via a buffered channel,
+// which blocks new requests once every session is in use rather than
+// creating unbounded additional sessions.
+type SessionPool struct {
+	sessions chan *pooledSession
+}
+
+// Creates a new SessionPool containing poolSize independent sessions, each
+// loading the network at modelPath.
+func NewSessionPool(modelPath string, poolSize int) (*SessionPool, error) {
+	pool := &SessionPool{
+		sessions: make(chan *pooledSession, poolSize),
+	}
+	for i := 0; i < poolSize; i++ {
+		inputTensor, e := ort.NewEmptyTensor[float32](ort.NewShape(1, 1, 4))
+		if e != nil {
+			pool.Destroy()
+			return nil, fmt.Errorf("Error creating input tensor %d: %w", i, e)
+		}
+		outputTensor, e := ort.NewEmptyTensor[float32](ort.NewShape(1, 1, 2))
+		if e != nil {
+			inputTensor.Destroy()
+			pool.Destroy()
+			return nil, fmt.Errorf("Error creating output tensor %d: %w", i, e)
+		}
+		session, e := ort.NewAdvancedSession(modelPath,
+			[]string{"1x4 Input Vector"}, []string{"1x2 Output Vector"},
+			[]ort.ArbitraryTensor{inputTensor},
+			[]ort.ArbitraryTensor{outputTensor}, nil)
+		if e != nil {
+			inputTensor.Destroy()
+			outputTensor.Destroy()
+			pool.Destroy()
+			return nil, fmt.Errorf("Error creating session %d: %w", i, e)
+		}
+		pool.sessions <- &pooledSession{
+			session: session,
+			input:   inputTensor,
+			output:  outputTensor,
+		}
+	}
+	return pool, nil
+}
+
+// Blocks until a session is available or ctx is canceled. Returns the
+// session, which the caller must return to the pool via Put once finished,
+// or ctx's error if ctx was canceled first.
+func (pool *SessionPool) Get(ctx context.Context) (*pooledSession, error) {
+	select {
+	case s := <-pool.sessions:
+		return s, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Returns a session obtained from Get back to the pool.
+func (pool *SessionPool) Put(s *pooledSession) {
+	pool.sessions <- s
+}
+
+// Destroys every session currently in the pool. Must only be called once
+// every outstanding session has been returned via Put, and no further
+// calls to Get will be made.
+func (pool *SessionPool) Destroy() {
+	close(pool.sessions)
+	for s := range pool.sessions {
+		s.destroy()
+	}
+}
+
+// The JSON request body accepted by POST /infer.
+type inferRequest struct {
+	Input []float32 `json:"input"`
+}
+
+// The JSON response body returned by POST /infer.
+type inferResponse struct {
+	Output []float32 `json:"output"`
+}
+
+// Returns an http.Handler implementing POST /infer using pool.
+func newInferHandler(pool *SessionPool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		var req inferRequest
+		if e := json.NewDecoder(r.Body).Decode(&req); e != nil {
+			http.Error(w, fmt.Sprintf("Invalid JSON request: %s", e),
+				http.StatusBadRequest)
+			return
+		}
+		if len(req.Input) != 4 {
+			http.Error(w, "The \"input\" array must contain exactly 4 "+
+				"values", http.StatusBadRequest)
+			return
+		}
+
+		session, e := pool.Get(r.Context())
+		if e != nil {
+			http.Error(w, "Request canceled while waiting for an available "+
+				"session",
+				http.StatusServiceUnavailable)
+			return
+		}
+		output, e := session.run(req.Input)
+		pool.Put(session)
+		if e != nil {
+			http.Error(w, fmt.Sprintf("Error running the network: %s", e),
+				http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(inferResponse{Output: output})
+	}
+}
+
+// Holds the command-line configuration for this example.
+type config struct {
+	onnxruntimeLibPath string
+	modelPath          string
+	listenAddr         string
+	poolSize           int
+	shutdownTimeout    time.Duration
+}
+
+func runServer(c config) error {
+	ort.SetSharedLibraryPath(c.onnxruntimeLibPath)
+	e := ort.InitializeEnvironment()
+	if e != nil {
+		return fmt.Errorf("Error initializing the onnxruntime library: %w", e)
+	}
+	defer ort.DestroyEnvironment()
+
+	pool, e := NewSessionPool(c.modelPath, c.poolSize)
+	if e != nil {
+		return fmt.Errorf("Error creating the session pool: %w", e)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/infer", newInferHandler(pool))
+	server := &http.Server{
+		Addr:    c.listenAddr,
+		Handler: mux,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		fmt.Printf("Listening on %s with a pool of %d sessions.\n",
+			c.listenAddr, c.poolSize)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case e := <-serveErr:
+		if e != nil && e != http.ErrServerClosed {
+			pool.Destroy()
+			return fmt.Errorf("Error running the HTTP server: %w", e)
+		}
+	case <-stop:
+		fmt.Println("Shutting down; draining in-flight requests...")
+		ctx, cancel := context.WithTimeout(context.Background(),
+			c.shutdownTimeout)
+		defer cancel()
+		if e := server.Shutdown(ctx); e != nil {
+			pool.Destroy()
+			return fmt.Errorf("Error shutting down the HTTP server: %w", e)
+		}
+	}
+
+	// By the time Shutdown (or ListenAndServe) has returned, every handler
+	// has finished and returned its session to the pool, so it's now safe
+	// to destroy every session and tensor in the pool.
+	pool.Destroy()
+	fmt.Println("Shut down cleanly.")
+	return nil
+}
+
+func run() int {
+	var c config
+	flag.StringVar(&c.onnxruntimeLibPath, "onnxruntime_lib",
+		getDefaultSharedLibPath(),
+		"The path to the onnxruntime shared library for your system.")
+	flag.StringVar(&c.modelPath, "model",
+		"../sum_and_difference/sum_and_difference.onnx",
+		"The path to the ONNX model to serve.")
+	flag.StringVar(&c.listenAddr, "listen", "127.0.0.1:8080",
+		"The address to listen for HTTP requests on.")
+	flag.IntVar(&c.poolSize, "pool_size", runtime.NumCPU(),
+		"The number of pre-built sessions to keep in the pool. This "+
+			"bounds the number of inference requests that can run "+
+			"concurrently.")
+	flag.DurationVar(&c.shutdownTimeout, "shutdown_timeout", 10*time.Second,
+		"How long to wait for in-flight requests to finish when shutting "+
+			"down.")
+	flag.Parse()
+	if c.onnxruntimeLibPath == "" {
+		fmt.Println("You must specify a path to the onnxruntime shared " +
+			"on your system. Run with -help for more information.")
+		return 1
+	}
+
+	e := runServer(c)
+	if e != nil {
+		fmt.Printf("Encountered an error running the server: %s\n", e)
+		return 1
+	}
+	return 0
+}
+
+func main() {
+	os.Exit(run())
+}
@@ -0,0 +1,211 @@
+// Package tokenizer implements the minimum amount of WordPiece tokenization
+// needed to run a HuggingFace BERT-family encoder (such as
+// all-MiniLM-L6-v2, or a distilled BERT sentiment classifier) exported to
+// ONNX: basic whitespace/punctuation splitting, lowercasing, and greedy
+// longest-match-first subword tokenization against a vocabulary.
+//
+// This deliberately reads the plain "vocab.txt" format (one token per
+// line, where the line number is the token's ID) that ships alongside
+// HuggingFace's BERT-family tokenizers, rather than parsing the full
+// tokenizer.json schema (which additionally encodes normalizers, a
+// pre-tokenizer pipeline, and merge rules for BPE-based tokenizers). That
+// covers WordPiece models, which is what the bundled examples use; a
+// BPE-based model such as GPT-2 would need a different Tokenizer
+// implementation.
+package tokenizer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// The special tokens a BERT-family WordPiece vocabulary is expected to
+// define.
+const (
+	unkToken = "[UNK]"
+	clsToken = "[CLS]"
+	sepToken = "[SEP]"
+	padToken = "[PAD]"
+)
+
+// A Tokenizer turns strings into the token ID sequences a WordPiece-based
+// encoder model expects.
+type Tokenizer struct {
+	tokenToID map[string]int64
+	unkID     int64
+	clsID     int64
+	sepID     int64
+	padID     int64
+	// The maximum number of characters a single whitespace-delimited word
+	// may contain before it's treated as unknown outright, matching the
+	// reference WordPiece tokenizer's behavior.
+	maxInputCharsPerWord int
+}
+
+// Loads a WordPiece vocabulary from a vocab.txt file (one token per line,
+// in ID order) and returns a Tokenizer that uses it.
+func LoadVocab(path string) (*Tokenizer, error) {
+	f, e := os.Open(path)
+	if e != nil {
+		return nil, fmt.Errorf("Error opening vocab file: %w", e)
+	}
+	defer f.Close()
+
+	t := &Tokenizer{
+		tokenToID:            make(map[string]int64),
+		maxInputCharsPerWord: 100,
+	}
+	scanner := bufio.NewScanner(f)
+	var id int64
+	for scanner.Scan() {
+		token := scanner.Text()
+		t.tokenToID[token] = id
+		id++
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, fmt.Errorf("Error reading vocab file: %w", e)
+	}
+
+	var ok bool
+	t.unkID, ok = t.tokenToID[unkToken]
+	if !ok {
+		return nil, fmt.Errorf("Vocab file is missing the %s token",
+			unkToken)
+	}
+	t.clsID, ok = t.tokenToID[clsToken]
+	if !ok {
+		return nil, fmt.Errorf("Vocab file is missing the %s token",
+			clsToken)
+	}
+	t.sepID, ok = t.tokenToID[sepToken]
+	if !ok {
+		return nil, fmt.Errorf("Vocab file is missing the %s token",
+			sepToken)
+	}
+	t.padID, ok = t.tokenToID[padToken]
+	if !ok {
+		return nil, fmt.Errorf("Vocab file is missing the %s token",
+			padToken)
+	}
+	return t, nil
+}
+
+// Lowercases text and splits it into words on whitespace, additionally
+// splitting punctuation characters off into their own words. This mirrors
+// BERT's BasicTokenizer step, which runs before WordPiece subword
+// splitting.
+func basicTokenize(text string) []string {
+	var words []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			flush()
+			words = append(words, string(r))
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return words
+}
+
+// Splits a single word into WordPiece subword tokens using greedy
+// longest-match-first matching against the vocabulary: repeatedly finds the
+// longest prefix of the remaining characters that's present in the
+// vocabulary (prefixing the prefix with "##" for every piece after the
+// first), and falls back to a single [UNK] token for the whole word if any
+// remaining piece can't be matched.
+func (t *Tokenizer) wordPiece(word string) []int64 {
+	runes := []rune(word)
+	if len(runes) > t.maxInputCharsPerWord {
+		return []int64{t.unkID}
+	}
+	var ids []int64
+	start := 0
+	for start < len(runes) {
+		end := len(runes)
+		var matchedID int64 = -1
+		for end > start {
+			candidate := string(runes[start:end])
+			if start > 0 {
+				candidate = "##" + candidate
+			}
+			if id, ok := t.tokenToID[candidate]; ok {
+				matchedID = id
+				break
+			}
+			end--
+		}
+		if matchedID < 0 {
+			return []int64{t.unkID}
+		}
+		ids = append(ids, matchedID)
+		start = end
+	}
+	return ids
+}
+
+// Tokenizes text into a sequence of vocabulary IDs, without adding any
+// special tokens.
+func (t *Tokenizer) tokenizeToIDs(text string) []int64 {
+	var ids []int64
+	for _, word := range basicTokenize(text) {
+		ids = append(ids, t.wordPiece(word)...)
+	}
+	return ids
+}
+
+// The tensors needed to run a single sequence through a BERT-family
+// encoder: each is a flat []int64 of length maxLen, ready to be wrapped in
+// an ort.NewTensor[int64].
+type Encoded struct {
+	InputIDs      []int64
+	AttentionMask []int64
+	TokenTypeIDs  []int64
+}
+
+// Tokenizes text, adds the [CLS] and [SEP] special tokens, and pads or
+// truncates the result to exactly maxLen tokens, returning the InputIDs,
+// AttentionMask, and TokenTypeIDs tensors a single-sequence BERT-family
+// model expects. TokenTypeIDs is always all zeros, since this only
+// supports single-sequence inputs (not sentence pairs).
+func (t *Tokenizer) Encode(text string, maxLen int) Encoded {
+	ids := t.tokenizeToIDs(text)
+	// Reserve room for [CLS] and [SEP].
+	if len(ids) > maxLen-2 {
+		ids = ids[:maxLen-2]
+	}
+
+	result := Encoded{
+		InputIDs:      make([]int64, maxLen),
+		AttentionMask: make([]int64, maxLen),
+		TokenTypeIDs:  make([]int64, maxLen),
+	}
+	result.InputIDs[0] = t.clsID
+	result.AttentionMask[0] = 1
+	i := 1
+	for _, id := range ids {
+		result.InputIDs[i] = id
+		result.AttentionMask[i] = 1
+		i++
+	}
+	result.InputIDs[i] = t.sepID
+	result.AttentionMask[i] = 1
+	i++
+	for ; i < maxLen; i++ {
+		result.InputIDs[i] = t.padID
+	}
+	return result
+}
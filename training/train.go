@@ -0,0 +1,313 @@
+// This example fine-tunes the tiny regression model produced by
+// generate_training_assets.py using onnxruntime's on-device training API:
+// it loads the training_model.onnx, optimizer_model.onnx, and checkpoint
+// artifacts that script generates, iterates minibatches drawn from a fixed
+// in-memory dataset, periodically evaluates loss on a held-out split of
+// that same dataset, and finally exports the trained inference model.
+//
+// IMPORTANT: onnxruntime deprecated its training C API in onnxruntime
+// 1.20.0, and onnxruntime_go versions after v1.12.1 only keep the
+// TrainingSession types around as stubs that return an error explaining as
+// much (see onnxruntime_go's legacy_code.go). To actually run this example,
+// pin `github.com/yalue/onnxruntime_go` to v1.12.1 in this example's
+// go.mod, and use a training-enabled build of the onnxruntime shared
+// library (the CPU/GPU inference-only builds under third_party won't work,
+// since they're missing the OrtTrainingApi entry points). This is otherwise
+// an ordinary use of that release's training API: no additional wrapper
+// code is needed beyond what v1.12.1 already exposes.
+package main
+
+import (
+	"flag"
+	"fmt"
+	ort "github.com/yalue/onnxruntime_go"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Attempts to find and return a path to a version of the onnxruntime shared
+// library compatible with the current OS and system architecture. As
+// explained in the package doc comment, this must be a training-enabled
+// build to work with this particular example.
+func getDefaultSharedLibPath() string {
+	if runtime.GOOS == "windows" {
+		if runtime.GOARCH == "amd64" {
+			return "../third_party/onnxruntime.dll"
+		}
+	}
+	if runtime.GOOS == "darwin" {
+		if runtime.GOARCH == "arm64" {
+			return "../third_party/onnxruntime_arm64.dylib"
+		}
+	}
+	if runtime.GOOS == "linux" {
+		if runtime.GOARCH == "arm64" {
+			return "../third_party/onnxruntime_arm64.so"
+		}
+		return "../third_party/onnxruntime.so"
+	}
+	fmt.Printf("Unable to determine a path to the onnxruntime shared library"+
+		" for OS \"%s\" and architecture \"%s\".\n", runtime.GOOS,
+		runtime.GOARCH)
+	return ""
+}
+
+// Holds the command-line configuration for this example.
+type config struct {
+	onnxruntimeLibPath string
+	artifactsDir       string
+	outputModelPath    string
+	trainSize          int
+	evalSize           int
+	batchSize          int
+	epochs             int
+	evalEveryNEpochs   int
+}
+
+// A single example of the toy regression task also used by
+// sum_and_difference: given 4 random inputs, predict their sum and their
+// max pairwise difference.
+type example struct {
+	input  [4]float32
+	target [2]float32
+}
+
+// Generates a fixed dataset of n examples for the toy regression task,
+// using rng for reproducibility. The caller is expected to partition the
+// result into a training set and a held-out evaluation set once, rather
+// than generating fresh examples for each split on every use; otherwise
+// "evaluation" is just more i.i.d. training data rather than a genuine
+// held-out split.
+func generateDataset(rng *rand.Rand, n int) []example {
+	dataset := make([]example, n)
+	for i := range dataset {
+		var sum, min, max float32
+		min = 1
+		max = -1
+		for j := 0; j < 4; j++ {
+			v := rng.Float32()
+			dataset[i].input[j] = v
+			sum += v
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+		dataset[i].target[0] = sum
+		dataset[i].target[1] = max - min
+	}
+	return dataset
+}
+
+// Flattens batchSize consecutive examples from dataset, starting at start
+// and wrapping around to the beginning once the end is reached, into the
+// flat []float32 slices that ort.NewTensor expects.
+func flattenBatch(dataset []example, start, batchSize int) (input, target []float32) {
+	input = make([]float32, batchSize*4)
+	target = make([]float32, batchSize*2)
+	for i := 0; i < batchSize; i++ {
+		e := dataset[(start+i)%len(dataset)]
+		copy(input[i*4:i*4+4], e.input[:])
+		copy(target[i*2:i*2+2], e.target[:])
+	}
+	return input, target
+}
+
+// Flattens every example in dataset into the flat []float32 slices that
+// ort.NewTensor expects.
+func flattenAll(dataset []example) (input, target []float32) {
+	return flattenBatch(dataset, 0, len(dataset))
+}
+
+// Exports session's current (live, being-updated) parameters to
+// snapshotPath, then runs the resulting inference-only model over every
+// example in evalSet, returning the mean squared error between its
+// predictions and the expected targets. TrainingSession exposes no direct
+// eval step, so this is how evaluation stays connected to the parameters
+// the training loop is actually updating, rather than to whatever
+// eval_model.onnx's initializers happened to contain at export time.
+func evaluate(session *ort.TrainingSession, snapshotPath string,
+	evalSet []example) (float32, error) {
+	if e := session.ExportModel(snapshotPath, []string{"output"}); e != nil {
+		return 0, fmt.Errorf("Error exporting a snapshot for evaluation: %w",
+			e)
+	}
+
+	inputData, targetData := flattenAll(evalSet)
+	inputTensor, e := ort.NewTensor(ort.NewShape(int64(len(evalSet)), 4),
+		inputData)
+	if e != nil {
+		return 0, fmt.Errorf("Error creating the eval input tensor: %w", e)
+	}
+	defer inputTensor.Destroy()
+	outputTensor, e := ort.NewEmptyTensor[float32](ort.NewShape(
+		int64(len(evalSet)), 2))
+	if e != nil {
+		return 0, fmt.Errorf("Error creating the eval output tensor: %w", e)
+	}
+	defer outputTensor.Destroy()
+
+	infSession, e := ort.NewAdvancedSession(snapshotPath,
+		[]string{"input"}, []string{"output"},
+		[]ort.ArbitraryTensor{inputTensor},
+		[]ort.ArbitraryTensor{outputTensor}, nil)
+	if e != nil {
+		return 0, fmt.Errorf("Error loading the exported snapshot: %w", e)
+	}
+	defer infSession.Destroy()
+	if e := infSession.Run(); e != nil {
+		return 0, fmt.Errorf("Error running the exported snapshot: %w", e)
+	}
+
+	predicted := outputTensor.GetData()
+	var sumSquaredError float64
+	for i, expected := range targetData {
+		diff := float64(predicted[i] - expected)
+		sumSquaredError += diff * diff
+	}
+	return float32(sumSquaredError / float64(len(targetData))), nil
+}
+
+func runTraining(c config) error {
+	ort.SetSharedLibraryPath(c.onnxruntimeLibPath)
+	e := ort.InitializeEnvironment()
+	if e != nil {
+		return fmt.Errorf("Error initializing the onnxruntime library: %w", e)
+	}
+	defer ort.DestroyEnvironment()
+
+	if !ort.IsTrainingSupported() {
+		return fmt.Errorf("This onnxruntime shared library was not built " +
+			"with training support; see this example's package doc " +
+			"comment for details")
+	}
+
+	checkpointPath := filepath.Join(c.artifactsDir, "checkpoint")
+	trainingModelPath := filepath.Join(c.artifactsDir, "training_model.onnx")
+	evalModelPath := filepath.Join(c.artifactsDir, "eval_model.onnx")
+	optimizerModelPath := filepath.Join(c.artifactsDir,
+		"optimizer_model.onnx")
+	snapshotPath := filepath.Join(c.artifactsDir, "eval_snapshot.onnx")
+
+	// Generate the full dataset once, up front, and split it into a
+	// training set and a held-out evaluation set that the training loop
+	// never trains on.
+	dataset := generateDataset(rand.New(rand.NewSource(1234)),
+		c.trainSize+c.evalSize)
+	trainSet := dataset[:c.trainSize]
+	evalSet := dataset[c.trainSize:]
+
+	inputTensor, e := ort.NewEmptyTensor[float32](ort.NewShape(
+		int64(c.batchSize), 4))
+	if e != nil {
+		return fmt.Errorf("Error creating the input tensor: %w", e)
+	}
+	defer inputTensor.Destroy()
+	targetTensor, e := ort.NewEmptyTensor[float32](ort.NewShape(
+		int64(c.batchSize), 2))
+	if e != nil {
+		return fmt.Errorf("Error creating the target tensor: %w", e)
+	}
+	defer targetTensor.Destroy()
+	lossScalar, e := ort.NewEmptyScalar[float32]()
+	if e != nil {
+		return fmt.Errorf("Error creating the loss scalar: %w", e)
+	}
+	defer lossScalar.Destroy()
+
+	session, e := ort.NewTrainingSession(checkpointPath, trainingModelPath,
+		evalModelPath, optimizerModelPath,
+		[]ort.Value{inputTensor, targetTensor}, []ort.Value{lossScalar}, nil)
+	if e != nil {
+		return fmt.Errorf("Error creating the training session: %w", e)
+	}
+	defer session.Destroy()
+
+	trainOffset := 0
+	for epoch := 0; epoch < c.epochs; epoch++ {
+		inputData, targetData := flattenBatch(trainSet, trainOffset,
+			c.batchSize)
+		copy(inputTensor.GetData(), inputData)
+		copy(targetTensor.GetData(), targetData)
+		trainOffset = (trainOffset + c.batchSize) % len(trainSet)
+
+		if e := session.TrainStep(); e != nil {
+			return fmt.Errorf("Error running training step %d: %w", epoch, e)
+		}
+		trainLoss := lossScalar.GetData()
+		if e := session.OptimizerStep(); e != nil {
+			return fmt.Errorf("Error running optimizer step %d: %w", epoch, e)
+		}
+		if e := session.LazyResetGrad(); e != nil {
+			return fmt.Errorf("Error resetting gradients after step %d: %w",
+				epoch, e)
+		}
+
+		if (epoch%c.evalEveryNEpochs) != 0 && epoch != c.epochs-1 {
+			continue
+		}
+		evalLoss, e := evaluate(session, snapshotPath, evalSet)
+		if e != nil {
+			return fmt.Errorf("Error evaluating after step %d: %w", epoch, e)
+		}
+		fmt.Printf("Epoch %4d: train loss %.6f, eval loss %.6f\n", epoch,
+			trainLoss, evalLoss)
+	}
+	os.Remove(snapshotPath)
+
+	if e := session.ExportModel(c.outputModelPath, []string{"output"}); e != nil {
+		return fmt.Errorf("Error exporting the trained model: %w", e)
+	}
+	fmt.Printf("Wrote the trained inference model to %s\n", c.outputModelPath)
+	return nil
+}
+
+func run() int {
+	var c config
+	flag.StringVar(&c.onnxruntimeLibPath, "onnxruntime_lib",
+		getDefaultSharedLibPath(),
+		"The path to a training-enabled onnxruntime shared library.")
+	flag.StringVar(&c.artifactsDir, "artifacts_dir", "./training_artifacts",
+		"The directory containing the checkpoint, training_model.onnx, "+
+			"eval_model.onnx, and optimizer_model.onnx files produced by "+
+			"generate_training_assets.py.")
+	flag.StringVar(&c.outputModelPath, "output_model",
+		"./trained_inference.onnx",
+		"Where to write the final trained inference model.")
+	flag.IntVar(&c.trainSize, "train_size", 320,
+		"The number of examples in the fixed training set.")
+	flag.IntVar(&c.evalSize, "eval_size", 64,
+		"The number of examples in the fixed, held-out evaluation set.")
+	flag.IntVar(&c.batchSize, "batch_size", 16,
+		"The number of examples per minibatch.")
+	flag.IntVar(&c.epochs, "epochs", 500,
+		"The number of minibatches to train on.")
+	flag.IntVar(&c.evalEveryNEpochs, "eval_every", 50,
+		"How often, in epochs, to evaluate on the held-out split. Must "+
+			"be greater than zero.")
+	flag.Parse()
+	if c.onnxruntimeLibPath == "" {
+		fmt.Println("You must specify a path to the onnxruntime shared " +
+			"on your system. Run with -help for more information.")
+		return 1
+	}
+	if c.evalEveryNEpochs <= 0 {
+		fmt.Println("-eval_every must be greater than zero.")
+		return 1
+	}
+
+	e := runTraining(c)
+	if e != nil {
+		fmt.Printf("Encountered an error training the network: %s\n", e)
+		return 1
+	}
+	return 0
+}
+
+func main() {
+	os.Exit(run())
+}
@@ -0,0 +1,260 @@
+// This example loads a small transformer encoder exported to ONNX (for
+// example, sentence-transformers/all-MiniLM-L6-v2) and computes a
+// normalized sentence embedding for each -text string passed on the
+// command line, then prints the pairwise cosine similarity between them.
+//
+// It demonstrates the multi-input, integer-tensor shape most transformer
+// encoders require: input_ids, attention_mask, and token_type_ids, each an
+// int64 tensor built via ort.NewTensor[int64]. Tokenization is handled by
+// the tokenizer subpackage, which implements WordPiece tokenization
+// against a HuggingFace-style vocab.txt.
+package main
+
+import (
+	"flag"
+	"fmt"
+	ort "github.com/yalue/onnxruntime_go"
+	"math"
+	"os"
+	"runtime"
+
+	"github.com/yalue/onnxruntime_go_examples/tokenizer"
+)
+
+// Attempts to find and return a path to a version of the onnxruntime shared
+// library compatible with the current OS and system architecture.
+func getDefaultSharedLibPath() string {
+	if runtime.GOOS == "windows" {
+		if runtime.GOARCH == "amd64" {
+			return "../third_party/onnxruntime.dll"
+		}
+	}
+	if runtime.GOOS == "darwin" {
+		if runtime.GOARCH == "arm64" {
+			return "../third_party/onnxruntime_arm64.dylib"
+		}
+	}
+	if runtime.GOOS == "linux" {
+		if runtime.GOARCH == "arm64" {
+			return "../third_party/onnxruntime_arm64.so"
+		}
+		return "../third_party/onnxruntime.so"
+	}
+	fmt.Printf("Unable to determine a path to the onnxruntime shared library"+
+		" for OS \"%s\" and architecture \"%s\".\n", runtime.GOOS,
+		runtime.GOARCH)
+	return ""
+}
+
+// Supports passing -text multiple times on the command line.
+type stringList []string
+
+func (s *stringList) String() string {
+	return fmt.Sprintf("%v", []string(*s))
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// Holds the command-line configuration for this example.
+type config struct {
+	onnxruntimeLibPath string
+	modelPath          string
+	vocabPath          string
+	texts              stringList
+	inputIDsName       string
+	attentionMaskName  string
+	tokenTypeIDsName   string
+	outputName         string
+	maxSeqLen          int
+}
+
+// Mean-pools a [seqLen, hiddenSize] last-hidden-state tensor into a single
+// hiddenSize-dimensional vector, weighting each token's contribution by its
+// attention mask so that padding tokens don't affect the result, then
+// L2-normalizes the result. This is the standard sentence-embedding
+// pooling strategy used by sentence-transformers models.
+func meanPool(lastHiddenState []float32, attentionMask []int64,
+	seqLen, hiddenSize int) []float32 {
+	pooled := make([]float32, hiddenSize)
+	var maskSum float32
+	for t := 0; t < seqLen; t++ {
+		mask := float32(attentionMask[t])
+		maskSum += mask
+		for h := 0; h < hiddenSize; h++ {
+			pooled[h] += lastHiddenState[t*hiddenSize+h] * mask
+		}
+	}
+	if maskSum == 0 {
+		maskSum = 1
+	}
+	var norm float64
+	for h := range pooled {
+		pooled[h] /= maskSum
+		norm += float64(pooled[h]) * float64(pooled[h])
+	}
+	norm = math.Sqrt(norm)
+	if norm == 0 {
+		return pooled
+	}
+	for h := range pooled {
+		pooled[h] = float32(float64(pooled[h]) / norm)
+	}
+	return pooled
+}
+
+// Returns the cosine similarity between two equal-length, already-
+// normalized vectors (i.e. their dot product).
+func cosineSimilarity(a, b []float32) float32 {
+	var dot float32
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return dot
+}
+
+// Runs text through session, returning its normalized sentence embedding.
+func embed(session *ort.DynamicAdvancedSession, tok *tokenizer.Tokenizer,
+	text string, maxSeqLen int) ([]float32, error) {
+	encoded := tok.Encode(text, maxSeqLen)
+
+	inputIDs, e := ort.NewTensor(ort.NewShape(1, int64(maxSeqLen)),
+		encoded.InputIDs)
+	if e != nil {
+		return nil, fmt.Errorf("Error creating the input_ids tensor: %w", e)
+	}
+	defer inputIDs.Destroy()
+
+	attentionMask, e := ort.NewTensor(ort.NewShape(1, int64(maxSeqLen)),
+		encoded.AttentionMask)
+	if e != nil {
+		return nil, fmt.Errorf("Error creating the attention_mask "+
+			"tensor: %w", e)
+	}
+	defer attentionMask.Destroy()
+
+	tokenTypeIDs, e := ort.NewTensor(ort.NewShape(1, int64(maxSeqLen)),
+		encoded.TokenTypeIDs)
+	if e != nil {
+		return nil, fmt.Errorf("Error creating the token_type_ids "+
+			"tensor: %w", e)
+	}
+	defer tokenTypeIDs.Destroy()
+
+	outputs := []ort.Value{nil}
+	e = session.Run([]ort.Value{inputIDs, attentionMask, tokenTypeIDs},
+		outputs)
+	if e != nil {
+		return nil, fmt.Errorf("Error running the network: %w", e)
+	}
+	lastHiddenState, ok := outputs[0].(*ort.Tensor[float32])
+	if !ok {
+		return nil, fmt.Errorf("Expected a float32 output tensor, got %T",
+			outputs[0])
+	}
+	defer lastHiddenState.Destroy()
+
+	shape := lastHiddenState.GetShape()
+	if len(shape) != 3 {
+		return nil, fmt.Errorf("Expected a 3-dimensional last_hidden_state "+
+			"tensor, got shape %v", shape)
+	}
+	hiddenSize := int(shape[2])
+	return meanPool(lastHiddenState.GetData(), encoded.AttentionMask,
+		maxSeqLen, hiddenSize), nil
+}
+
+func runExample(c config) error {
+	ort.SetSharedLibraryPath(c.onnxruntimeLibPath)
+	e := ort.InitializeEnvironment()
+	if e != nil {
+		return fmt.Errorf("Error initializing the onnxruntime library: %w", e)
+	}
+	defer ort.DestroyEnvironment()
+
+	tok, e := tokenizer.LoadVocab(c.vocabPath)
+	if e != nil {
+		return fmt.Errorf("Error loading the vocabulary: %w", e)
+	}
+
+	session, e := ort.NewDynamicAdvancedSession(c.modelPath,
+		[]string{c.inputIDsName, c.attentionMaskName, c.tokenTypeIDsName},
+		[]string{c.outputName}, nil)
+	if e != nil {
+		return fmt.Errorf("Error creating the session: %w", e)
+	}
+	defer session.Destroy()
+
+	embeddings := make([][]float32, len(c.texts))
+	for i, text := range c.texts {
+		embedding, e := embed(session, tok, text, c.maxSeqLen)
+		if e != nil {
+			return fmt.Errorf("Error embedding %q: %w", text, e)
+		}
+		embeddings[i] = embedding
+	}
+
+	if len(c.texts) < 2 {
+		fmt.Printf("Computed a %d-dimensional embedding for %q.\n",
+			len(embeddings[0]), c.texts[0])
+		return nil
+	}
+	fmt.Println("Pairwise cosine similarities:")
+	for i := 0; i < len(c.texts); i++ {
+		for j := i + 1; j < len(c.texts); j++ {
+			sim := cosineSimilarity(embeddings[i], embeddings[j])
+			fmt.Printf("  %.4f  %q <-> %q\n", sim, c.texts[i], c.texts[j])
+		}
+	}
+	return nil
+}
+
+func run() int {
+	var c config
+	flag.StringVar(&c.onnxruntimeLibPath, "onnxruntime_lib",
+		getDefaultSharedLibPath(),
+		"The path to the onnxruntime shared library for your system.")
+	flag.StringVar(&c.modelPath, "model", "./all-MiniLM-L6-v2.onnx",
+		"The path to the ONNX transformer encoder model to run.")
+	flag.StringVar(&c.vocabPath, "vocab", "./vocab.txt",
+		"The path to the model's WordPiece vocab.txt file.")
+	flag.Var(&c.texts, "text",
+		"A string to embed. May be passed more than once to compare "+
+			"multiple strings' embeddings.")
+	flag.StringVar(&c.inputIDsName, "input_ids_name", "input_ids",
+		"The name of the network's input_ids input tensor.")
+	flag.StringVar(&c.attentionMaskName, "attention_mask_name",
+		"attention_mask", "The name of the network's attention_mask "+
+			"input tensor.")
+	flag.StringVar(&c.tokenTypeIDsName, "token_type_ids_name",
+		"token_type_ids", "The name of the network's token_type_ids "+
+			"input tensor.")
+	flag.StringVar(&c.outputName, "output_name", "last_hidden_state",
+		"The name of the network's last-hidden-state output tensor.")
+	flag.IntVar(&c.maxSeqLen, "max_seq_len", 128,
+		"The sequence length to pad or truncate every input to.")
+	flag.Parse()
+	if c.onnxruntimeLibPath == "" {
+		fmt.Println("You must specify a path to the onnxruntime shared " +
+			"on your system. Run with -help for more information.")
+		return 1
+	}
+	if len(c.texts) == 0 {
+		fmt.Println("You must specify at least one -text to embed. Run " +
+			"with -help for more information.")
+		return 1
+	}
+
+	e := runExample(c)
+	if e != nil {
+		fmt.Printf("Encountered an error computing embeddings: %s\n", e)
+		return 1
+	}
+	return 0
+}
+
+func main() {
+	os.Exit(run())
+}
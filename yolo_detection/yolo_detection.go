@@ -0,0 +1,272 @@
+// This example runs a YOLOv8 object-detection model (or any model whose
+// output uses YOLOv8's [1, 4 + numClasses, numBoxes] layout) and writes an
+// annotated copy of the input image, with bounding boxes drawn around
+// detected objects, to disk. The original YOLOv5 output layout, with a
+// per-box objectness score, is not handled.
+//
+// Unlike the other examples in this repository, the input image size isn't
+// fixed ahead of time: this program lets the caller pass an arbitrary
+// -input_size on the command line (it must still be a multiple of the
+// model's stride, typically 32, for the model to accept it), and uses
+// ort.NewDynamicAdvancedSession so the session itself doesn't need to be
+// rebuilt for a different size. The network's output tensor shape also
+// isn't known ahead of time for the same reason, so this lets onnxruntime
+// allocate the output tensor rather than creating one up front.
+//
+// Preprocessing (letterboxing) and postprocessing (NMS and drawing) reuse
+// the imageutil subpackage introduced by the image_classification example.
+package main
+
+import (
+	"flag"
+	"fmt"
+	ort "github.com/yalue/onnxruntime_go"
+	"os"
+	"runtime"
+
+	"github.com/yalue/onnxruntime_go_examples/imageutil"
+)
+
+// Attempts to find and return a path to a version of the onnxruntime shared
+// library compatible with the current OS and system architecture.
+func getDefaultSharedLibPath() string {
+	if runtime.GOOS == "windows" {
+		if runtime.GOARCH == "amd64" {
+			return "../third_party/onnxruntime.dll"
+		}
+	}
+	if runtime.GOOS == "darwin" {
+		if runtime.GOARCH == "arm64" {
+			return "../third_party/onnxruntime_arm64.dylib"
+		}
+	}
+	if runtime.GOOS == "linux" {
+		if runtime.GOARCH == "arm64" {
+			return "../third_party/onnxruntime_arm64.so"
+		}
+		return "../third_party/onnxruntime.so"
+	}
+	fmt.Printf("Unable to determine a path to the onnxruntime shared library"+
+		" for OS \"%s\" and architecture \"%s\".\n", runtime.GOOS,
+		runtime.GOARCH)
+	return ""
+}
+
+// Reads a newline-delimited list of class labels from path. Returns a nil
+// slice (not an error) if path is empty, in which case detections will be
+// labeled by class index instead of name.
+func readLabels(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, e := os.ReadFile(path)
+	if e != nil {
+		return nil, fmt.Errorf("Error reading labels file: %w", e)
+	}
+	var labels []string
+	start := 0
+	for i, c := range data {
+		if c == '\n' {
+			labels = append(labels, string(data[start:i]))
+			start = i + 1
+		}
+	}
+	if start < len(data) {
+		labels = append(labels, string(data[start:]))
+	}
+	return labels, nil
+}
+
+// Holds the command-line configuration for this example.
+type config struct {
+	onnxruntimeLibPath string
+	modelPath          string
+	imagePath          string
+	outputPath         string
+	labelsPath         string
+	inputName          string
+	outputName         string
+	inputSize          int
+	scoreThreshold     float64
+	iouThreshold       float64
+	classAgnosticNMS   bool
+}
+
+// Decodes the raw [1, numAttrs, numBoxes] YOLOv8-style output tensor into a
+// flat list of candidate detections (before NMS has been applied), keeping
+// only those whose best class score is at least scoreThreshold. numAttrs is
+// assumed to be 4 (box) + numClasses; boxes are decoded from (centerX,
+// centerY, width, height) into (x1, y1, x2, y2) corners.
+func decodeYOLOv8Output(data []float32, numAttrs, numBoxes int,
+	scoreThreshold float32) []imageutil.Detection {
+	numClasses := numAttrs - 4
+	detections := make([]imageutil.Detection, 0, 32)
+	for box := 0; box < numBoxes; box++ {
+		bestScore := float32(0)
+		bestClass := -1
+		for class := 0; class < numClasses; class++ {
+			score := data[(4+class)*numBoxes+box]
+			if score > bestScore {
+				bestScore = score
+				bestClass = class
+			}
+		}
+		if bestClass < 0 || bestScore < scoreThreshold {
+			continue
+		}
+		cx := data[0*numBoxes+box]
+		cy := data[1*numBoxes+box]
+		w := data[2*numBoxes+box]
+		h := data[3*numBoxes+box]
+		detections = append(detections, imageutil.Detection{
+			X1:      cx - w/2,
+			Y1:      cy - h/2,
+			X2:      cx + w/2,
+			Y2:      cy + h/2,
+			ClassID: bestClass,
+			Score:   bestScore,
+		})
+	}
+	return detections
+}
+
+func runDetector(c config) error {
+	ort.SetSharedLibraryPath(c.onnxruntimeLibPath)
+	e := ort.InitializeEnvironment()
+	if e != nil {
+		return fmt.Errorf("Error initializing the onnxruntime library: %w", e)
+	}
+	defer ort.DestroyEnvironment()
+
+	labels, e := readLabels(c.labelsPath)
+	if e != nil {
+		return e
+	}
+
+	img, e := imageutil.DecodeImageFile(c.imagePath)
+	if e != nil {
+		return fmt.Errorf("Error decoding %s: %w", c.imagePath, e)
+	}
+	letterboxed, letterboxInfo := imageutil.Letterbox(img, c.inputSize)
+	inputData := imageutil.ToCHWFloat32(letterboxed, [3]float32{0, 0, 0},
+		[3]float32{1, 1, 1})
+
+	inputTensor, e := ort.NewTensor(ort.NewShape(1, 3, int64(c.inputSize),
+		int64(c.inputSize)), inputData)
+	if e != nil {
+		return fmt.Errorf("Error creating the input tensor: %w", e)
+	}
+	defer inputTensor.Destroy()
+
+	session, e := ort.NewDynamicAdvancedSession(c.modelPath,
+		[]string{c.inputName}, []string{c.outputName}, nil)
+	if e != nil {
+		return fmt.Errorf("Error creating the session: %w", e)
+	}
+	defer session.Destroy()
+
+	outputs := []ort.Value{nil}
+	e = session.Run([]ort.Value{inputTensor}, outputs)
+	if e != nil {
+		return fmt.Errorf("Error running the network: %w", e)
+	}
+	outputTensor, ok := outputs[0].(*ort.Tensor[float32])
+	if !ok {
+		return fmt.Errorf("Expected a float32 output tensor, got %T",
+			outputs[0])
+	}
+	defer outputTensor.Destroy()
+
+	shape := outputTensor.GetShape()
+	if len(shape) != 3 {
+		return fmt.Errorf("Expected a 3-dimensional output tensor, got "+
+			"shape %v", shape)
+	}
+	numAttrs := int(shape[1])
+	numBoxes := int(shape[2])
+
+	candidates := decodeYOLOv8Output(outputTensor.GetData(), numAttrs,
+		numBoxes, float32(c.scoreThreshold))
+	detections := imageutil.NMS(candidates, float32(c.iouThreshold),
+		c.classAgnosticNMS)
+
+	// Map box coordinates from the letterboxed input's coordinate space
+	// back to the original image's coordinate space before drawing.
+	for i := range detections {
+		detections[i].X1, detections[i].Y1 = letterboxInfo.MapToSource(
+			detections[i].X1, detections[i].Y1)
+		detections[i].X2, detections[i].Y2 = letterboxInfo.MapToSource(
+			detections[i].X2, detections[i].Y2)
+	}
+
+	e = imageutil.DrawDetections(img, detections, labels, c.outputPath)
+	if e != nil {
+		return fmt.Errorf("Error writing annotated image: %w", e)
+	}
+
+	fmt.Printf("Found %d objects:\n", len(detections))
+	for _, d := range detections {
+		label := fmt.Sprintf("class_%d", d.ClassID)
+		if d.ClassID >= 0 && d.ClassID < len(labels) {
+			label = labels[d.ClassID]
+		}
+		fmt.Printf("  %-20s %.2f%%  box=(%.0f, %.0f)-(%.0f, %.0f)\n", label,
+			d.Score*100, d.X1, d.Y1, d.X2, d.Y2)
+	}
+	fmt.Printf("Wrote annotated image to %s\n", c.outputPath)
+	return nil
+}
+
+func run() int {
+	var c config
+	flag.StringVar(&c.onnxruntimeLibPath, "onnxruntime_lib",
+		getDefaultSharedLibPath(),
+		"The path to the onnxruntime shared library for your system.")
+	flag.StringVar(&c.modelPath, "model", "./yolov8n.onnx",
+		"The path to the YOLOv8 (or compatible) ONNX model to run.")
+	flag.StringVar(&c.imagePath, "image", "",
+		"The path to a JPEG or PNG image to run detection on.")
+	flag.StringVar(&c.outputPath, "output", "./detections.png",
+		"Where to write the annotated output image.")
+	flag.StringVar(&c.labelsPath, "labels", "",
+		"Optional path to a newline-delimited list of class labels, such "+
+			"as the 80 COCO class names. If omitted, detections are "+
+			"labeled by class index.")
+	flag.StringVar(&c.inputName, "input_name", "images",
+		"The name of the network's input tensor.")
+	flag.StringVar(&c.outputName, "output_name", "output0",
+		"The name of the network's output tensor.")
+	flag.IntVar(&c.inputSize, "input_size", 640,
+		"The height and width, in pixels, to letterbox the input image "+
+			"to. Must be a multiple of the model's stride (usually 32).")
+	flag.Float64Var(&c.scoreThreshold, "score_threshold", 0.25,
+		"Minimum class confidence required to keep a candidate detection.")
+	flag.Float64Var(&c.iouThreshold, "iou_threshold", 0.45,
+		"Maximum IoU allowed between kept detections before NMS "+
+			"suppresses the lower-scoring one.")
+	flag.BoolVar(&c.classAgnosticNMS, "class_agnostic_nms", false,
+		"If set, NMS suppresses overlapping boxes regardless of their "+
+			"predicted class, rather than only within each class.")
+	flag.Parse()
+	if c.onnxruntimeLibPath == "" {
+		fmt.Println("You must specify a path to the onnxruntime shared " +
+			"on your system. Run with -help for more information.")
+		return 1
+	}
+	if c.imagePath == "" {
+		fmt.Println("You must specify an -image to run detection on. Run " +
+			"with -help for more information.")
+		return 1
+	}
+
+	e := runDetector(c)
+	if e != nil {
+		fmt.Printf("Encountered an error running detection: %s\n", e)
+		return 1
+	}
+	return 0
+}
+
+func main() {
+	os.Exit(run())
+}